@@ -1,29 +1,56 @@
 package run
 
 import (
+	"bytes"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/cli/cli/api"
+	"github.com/cli/cli/internal/config"
 	"github.com/cli/cli/internal/ghrepo"
 	"github.com/cli/cli/pkg/cmd/workflow/shared"
 	"github.com/cli/cli/pkg/cmdutil"
 	"github.com/cli/cli/pkg/iostreams"
+	"github.com/cli/cli/pkg/prompt"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 )
 
+// Input types supported by workflow_dispatch, mirroring what GitHub Actions
+// accepts for `on.workflow_dispatch.inputs.<input_id>.type`.
+const (
+	workflowInputTypeString      = "string"
+	workflowInputTypeBoolean     = "boolean"
+	workflowInputTypeChoice      = "choice"
+	workflowInputTypeNumber      = "number"
+	workflowInputTypeEnvironment = "environment"
+)
+
 type RunOptions struct {
 	HttpClient func() (*http.Client, error)
 	IO         *iostreams.IOStreams
 	BaseRepo   func() (ghrepo.Interface, error)
+	Config     func() (config.Config, error)
 
 	Selector string
 	Ref      string
@@ -32,38 +59,48 @@ type RunOptions struct {
 	JSON      string
 
 	Prompt bool
+
+	FromFile   string
+	KeepBranch bool
+
+	Watch bool
 }
 
 func NewCmdRun(f *cmdutil.Factory, runF func(*RunOptions) error) *cobra.Command {
 	opts := &RunOptions{
 		IO:         f.IOStreams,
 		HttpClient: f.HttpClient,
+		BaseRepo:   f.BaseRepo,
+		Config:     f.Config,
 	}
 
 	cmd := &cobra.Command{
 		Use:   "run [<workflow ID> | <workflow name>]",
 		Short: "Create a dispatch event for a workflow, starting a run",
-		Args: func(cmd *cobra.Command, args []string) error {
-			if cmd.ArgsLenAtDash() == 0 && len(args[1:]) > 0 {
-				return cmdutil.FlagError{Err: fmt.Errorf("workflow argument required when passing input flags")}
-			}
-			return nil
-		},
+		Args: cobra.MatchAll(
+			cobra.MinimumNArgs(0),
+			requireWorkflowBeforeInputArgs,
+		),
 		Hidden: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// support `-R, --repo` override
-			opts.BaseRepo = f.BaseRepo
-
 			if len(args) > 0 {
 				opts.Selector = args[0]
 				opts.InputArgs = args[1:]
+			} else if opts.FromFile != "" {
+				// the workflow comes from --from-file; no selector needed.
 			} else if !opts.IO.CanPrompt() {
 				return &cmdutil.FlagError{Err: errors.New("workflow ID or name required when not running interactively")}
 			} else {
 				opts.Prompt = true
 			}
 
-			if !opts.IO.IsStdinTTY() {
+			if strings.HasPrefix(opts.JSON, "@") {
+				jsonContent, err := readJSONArg(opts.IO, opts.JSON[1:])
+				if err != nil {
+					return fmt.Errorf("could not read JSON input: %w", err)
+				}
+				opts.JSON = jsonContent
+			} else if opts.JSON == "" && !opts.IO.IsStdinTTY() {
 				jsonIn, err := ioutil.ReadAll(opts.IO.In)
 				if err != nil {
 					return errors.New("failed to read from STDIN")
@@ -72,7 +109,7 @@ func NewCmdRun(f *cmdutil.Factory, runF func(*RunOptions) error) *cobra.Command
 			}
 
 			if opts.Selector == "" {
-				if opts.JSON != "" {
+				if opts.JSON != "" && opts.FromFile == "" {
 					return &cmdutil.FlagError{Err: errors.New("workflow argument required when passing JSON")}
 				}
 			} else {
@@ -88,21 +125,100 @@ func NewCmdRun(f *cmdutil.Factory, runF func(*RunOptions) error) *cobra.Command
 		},
 	}
 	cmd.Flags().StringVarP(&opts.Ref, "ref", "r", "", "The branch or tag name which contains the version of the workflow file you'd like to run")
-	cmd.Flags().StringVar(&opts.JSON, "json", "", "TODO")
+	cmd.Flags().StringVar(&opts.JSON, "json", "", "Pass workflow inputs as a JSON object, or @path to read the object from a file ('@-' to read from STDIN)")
+	cmd.Flags().StringVar(&opts.FromFile, "from-file", "", "Run a local, uncommitted workflow file by pushing it to a temporary branch")
+	cmd.Flags().BoolVar(&opts.KeepBranch, "keep-branch", false, "Keep the temporary branch created by --from-file instead of deleting it after the run is dispatched")
+	cmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Watch the run until it completes, exiting with a non-zero status if it fails")
 
 	return cmd
 }
 
+// requireWorkflowBeforeInputArgs rejects input flags passed after `--`
+// without a preceding workflow selector, e.g. `gh workflow run -- --name bob`.
+// A single arg right after `--` is not rejected; it is taken as the selector.
+func requireWorkflowBeforeInputArgs(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() == 0 && len(args) > 1 {
+		return cmdutil.FlagError{Err: errors.New("workflow argument required when passing input flags")}
+	}
+	return nil
+}
+
+// readJSONArg resolves the value of a `--json @path` argument, reading from
+// stdin when path is "-".
+func readJSONArg(io *iostreams.IOStreams, path string) (string, error) {
+	if path == "-" {
+		content, err := ioutil.ReadAll(io.In)
+		return string(content), err
+	}
+	content, err := ioutil.ReadFile(path)
+	return string(content), err
+}
+
 func runRun(opts *RunOptions) error {
+	workflowID, ref, yamlContent, cleanup, err := resolveWorkflowSource(opts)
+	if err != nil {
+		return err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	inputs, err := resolveInputs(opts, yamlContent)
+	if err != nil {
+		return err
+	}
+
+	return dispatch(opts, workflowID, ref, inputs)
+}
+
+// resolveWorkflowSource determines which workflow to dispatch and returns
+// its numeric workflow ID (for --from-file, the ID of the workflow already
+// registered at the same path on the default branch), the ref to dispatch
+// against, and the workflow's raw YAML content. The returned cleanup func,
+// which may be nil, must be deferred by the caller.
+func resolveWorkflowSource(opts *RunOptions) (workflowID string, ref string, yamlContent []byte, cleanup func(), err error) {
 	c, err := opts.HttpClient()
 	if err != nil {
-		return fmt.Errorf("could not build http client: %w", err)
+		return "", "", nil, nil, fmt.Errorf("could not build http client: %w", err)
 	}
 	client := api.NewClientFromHTTP(c)
 
 	repo, err := opts.BaseRepo()
 	if err != nil {
-		return fmt.Errorf("could not determine base repo: %w", err)
+		return "", "", nil, nil, fmt.Errorf("could not determine base repo: %w", err)
+	}
+
+	if opts.FromFile != "" {
+		yamlContent, err = ioutil.ReadFile(opts.FromFile)
+		if err != nil {
+			return "", "", nil, nil, fmt.Errorf("could not read workflow file: %w", err)
+		}
+
+		// workflow_dispatch can only be triggered for a workflow that is
+		// already registered with that trigger on the repo's default
+		// branch, regardless of what ref we ask it to run against. Fail
+		// fast with an actionable error instead of pushing a branch that
+		// can never be dispatched.
+		workflowID, err = resolveFromFileWorkflowID(client, repo, opts.FromFile)
+		if err != nil {
+			return "", "", nil, nil, err
+		}
+
+		cfg, err := opts.Config()
+		if err != nil {
+			return "", "", nil, nil, fmt.Errorf("could not read config: %w", err)
+		}
+		token, err := cfg.Get(repo.RepoHost(), "oauth_token")
+		if err != nil || token == "" {
+			return "", "", nil, nil, fmt.Errorf("could not find a token for %s; run 'gh auth login'", repo.RepoHost())
+		}
+
+		ref, cleanup, err = pushEphemeralWorkflow(client, repo, token, opts.FromFile, yamlContent, opts.KeepBranch)
+		if err != nil {
+			return "", "", nil, nil, fmt.Errorf("could not push %s to a temporary branch: %w", opts.FromFile, err)
+		}
+
+		return workflowID, ref, yamlContent, cleanup, nil
 	}
 
 	states := []shared.WorkflowState{shared.Active}
@@ -111,147 +227,723 @@ func runRun(opts *RunOptions) error {
 	if err != nil {
 		var fae shared.FilteredAllError
 		if errors.As(err, &fae) {
-			return errors.New("no workflows are enabled on this repository")
+			return "", "", nil, nil, errors.New("no workflows are enabled on this repository")
 		}
-		return err
+		return "", "", nil, nil, err
 	}
 
-	// TODO  once end-to-end is working, circle back and see if running a local workflow remotely is feasible by doing git stuff automagically in a throwaway branch.
-	ref := opts.Ref
-
+	ref = opts.Ref
 	if ref == "" {
 		ref, err = api.RepoDefaultBranch(client, repo)
 		if err != nil {
-			return fmt.Errorf("unable to determine default branch for %s: %w", ghrepo.FullName(repo), err)
+			return "", "", nil, nil, fmt.Errorf("unable to determine default branch for %s: %w", ghrepo.FullName(repo), err)
 		}
 	}
 
-	yamlContent, err := getWorkflowContent(client, repo, workflow, ref)
+	yamlContent, err = getWorkflowContent(client, repo, workflow, ref)
 	if err != nil {
-		return fmt.Errorf("unable to fetch workflow file content: %w", err)
+		return "", "", nil, nil, fmt.Errorf("unable to fetch workflow file content: %w", err)
 	}
 
+	return fmt.Sprintf("%d", workflow.ID), ref, yamlContent, nil, nil
+}
+
+// resolveInputs parses the workflow's declared inputs out of yamlContent and
+// gathers the values to dispatch with, either interactively, from --json, or
+// from the trailing `key=value` args, validating each against its declared
+// type along the way.
+func resolveInputs(opts *RunOptions, yamlContent []byte) (map[string]string, error) {
 	inputs, err := findInputs(yamlContent)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	providedInputs := map[string]string{}
+	for name, input := range inputs {
+		switch input.Type {
+		case "", workflowInputTypeString, workflowInputTypeBoolean, workflowInputTypeChoice, workflowInputTypeNumber, workflowInputTypeEnvironment:
+		default:
+			fmt.Fprintf(opts.IO.ErrOut, "warning: input '%s' has unrecognized type %q, treating it as a string\n", name, input.Type)
+		}
+	}
 
-	// TODO is opts.Prompt doing too much here?
-	if opts.Prompt {
-		// TODO survey version
-		return nil
-	} else {
-		if opts.JSON != "" {
-			err := json.Unmarshal([]byte(opts.JSON), providedInputs)
+	var environments []string
+	for _, input := range inputs {
+		if input.Type == workflowInputTypeEnvironment {
+			c, err := opts.HttpClient()
+			if err != nil {
+				return nil, fmt.Errorf("could not build http client: %w", err)
+			}
+			repo, err := opts.BaseRepo()
+			if err != nil {
+				return nil, fmt.Errorf("could not determine base repo: %w", err)
+			}
+			environments, err = fetchRepoEnvironments(api.NewClientFromHTTP(c), repo)
 			if err != nil {
-				return fmt.Errorf("could not parse provided JSON: %w", err)
+				return nil, fmt.Errorf("could not resolve repo environments: %w", err)
 			}
+			break
 		}
+	}
+
+	if opts.Prompt {
+		return collectInputs(inputs, environments)
+	}
+
+	providedInputs := map[string]string{}
 
-		if len(opts.InputArgs) > 0 {
-			fs := pflag.FlagSet{}
-			//var test string
-			for inputName, input := range inputs {
+	if opts.JSON != "" {
+		if err := json.Unmarshal([]byte(opts.JSON), &providedInputs); err != nil {
+			return nil, fmt.Errorf("could not parse provided JSON: %w", err)
+		}
+		for inputName, input := range inputs {
+			providedValue, ok := providedInputs[inputName]
+			if !ok || providedValue == "" {
+				if input.Required {
+					return nil, fmt.Errorf("missing required input '%s'", inputName)
+				}
+				continue
+			}
+			if err := validateProvidedInput(input, providedValue, environments); err != nil {
+				return nil, fmt.Errorf("input '%s': %w", inputName, err)
+			}
+		}
+	}
+
+	if len(opts.InputArgs) > 0 {
+		fs := pflag.FlagSet{}
+		for inputName, input := range inputs {
+			if input.Type == workflowInputTypeBoolean {
+				defaultValue, _ := strconv.ParseBool(input.Default)
+				fs.Bool(inputName, defaultValue, input.Description)
+			} else {
 				fs.String(inputName, input.Default, input.Description)
 			}
-			err = fs.Parse(opts.InputArgs)
-			if err != nil {
-				return fmt.Errorf("could not parse input args: %w", err)
+		}
+		if err := fs.Parse(opts.InputArgs); err != nil {
+			return nil, fmt.Errorf("could not parse input args: %w", err)
+		}
+		for inputName, input := range inputs {
+			var providedValue string
+			if input.Type == workflowInputTypeBoolean {
+				boolValue, _ := fs.GetBool(inputName)
+				providedValue = strconv.FormatBool(boolValue)
+			} else {
+				providedValue, _ = fs.GetString(inputName)
 			}
-			for inputName, input := range inputs {
-				// TODO error handling
-				providedValue, _ := fs.GetString(inputName)
 
-				if input.Required && providedValue == "" {
-					return fmt.Errorf("missing required input '%s'", inputName)
-				}
+			if input.Required && providedValue == "" {
+				return nil, fmt.Errorf("missing required input '%s'", inputName)
+			}
 
-				providedInputs[inputName] = providedValue
+			if providedValue != "" {
+				if err := validateProvidedInput(input, providedValue, environments); err != nil {
+					return nil, fmt.Errorf("input '%s': %w", inputName, err)
+				}
 			}
+
+			providedInputs[inputName] = providedValue
+		}
+	}
+
+	return providedInputs, nil
+}
+
+// dispatch posts the workflow_dispatch event and reports success to the
+// user, optionally following the resulting run to completion.
+func dispatch(opts *RunOptions, workflowID string, ref string, inputs map[string]string) error {
+	c, err := opts.HttpClient()
+	if err != nil {
+		return fmt.Errorf("could not build http client: %w", err)
+	}
+	client := api.NewClientFromHTTP(c)
+
+	repo, err := opts.BaseRepo()
+	if err != nil {
+		return fmt.Errorf("could not determine base repo: %w", err)
+	}
+
+	dispatchedAt := time.Now()
+	if err := createDispatch(client, repo, workflowID, ref, inputs); err != nil {
+		return fmt.Errorf("could not create workflow dispatch event: %w", err)
+	}
+
+	if opts.IO.IsStdoutTTY() {
+		cs := opts.IO.ColorScheme()
+		fmt.Fprintf(opts.IO.Out, "%s Created workflow_dispatch event for %s at %s\n",
+			cs.SuccessIcon(), workflowID, ref)
+		if !opts.Watch {
+			fmt.Fprintf(opts.IO.Out, "%s\n", cs.Gray("To see runs for this workflow, try: gh run list --workflow "+workflowID))
 		}
 	}
 
-	fmt.Printf("DBG %#v\n", providedInputs)
+	if !opts.Watch {
+		return nil
+	}
+
+	headSHA, err := resolveRefSHA(client, repo, ref)
+	if err != nil {
+		return fmt.Errorf("could not resolve %s to a commit to watch: %w", ref, err)
+	}
+
+	return watchRun(opts, client, repo, workflowID, ref, headSHA, dispatchedAt)
+}
+
+// resolveRefSHA returns the commit SHA that ref currently points to, used to
+// identify which run a dispatch actually created.
+func resolveRefSHA(client *api.Client, repo ghrepo.Interface, ref string) (string, error) {
+	path := fmt.Sprintf("repos/%s/commits/%s", ghrepo.FullName(repo), url.QueryEscape(ref))
+
+	type result struct {
+		SHA string `json:"sha"`
+	}
+
+	var res result
+	if err := client.REST(repo.RepoHost(), "GET", path, nil, &res); err != nil {
+		return "", err
+	}
+
+	return res.SHA, nil
+}
+
+// minPollInterval and maxPollInterval bound the exponential backoff used by
+// watchRun while polling for run and job status, to stay gentle on rate
+// limits without making the user wait too long between updates.
+const (
+	minPollInterval = time.Second
+	maxPollInterval = 15 * time.Second
+)
+
+type workflowRun struct {
+	ID         int64  `json:"id"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HeadSHA    string `json:"head_sha"`
+	HTMLURL    string `json:"html_url"`
+	WorkflowID int64  `json:"workflow_id"`
+}
+
+type workflowRunJob struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	Steps      []struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+		Number     int    `json:"number"`
+	} `json:"steps"`
+}
+
+// watchRun locates the run created by the preceding dispatch and polls it
+// until it reaches a terminal conclusion, printing a live summary of job and
+// step status as it goes. It returns a non-zero-exiting error if the run
+// did not conclude successfully.
+func watchRun(opts *RunOptions, client *api.Client, repo ghrepo.Interface, workflowID string, ref string, headSHA string, dispatchedAt time.Time) error {
+	run, err := findDispatchedRun(client, repo, workflowID, ref, headSHA, dispatchedAt)
+	if err != nil {
+		return fmt.Errorf("could not find the run created by this dispatch: %w", err)
+	}
+
+	cs := opts.IO.ColorScheme()
+	interval := minPollInterval
+
+	for {
+		run, err = getRun(client, repo, run.ID)
+		if err != nil {
+			return fmt.Errorf("could not fetch run status: %w", err)
+		}
+
+		jobs, err := getRunJobs(client, repo, run.ID)
+		if err != nil {
+			return fmt.Errorf("could not fetch job status: %w", err)
+		}
+
+		printRunStatus(opts.IO, cs, run, jobs)
+
+		if run.Status == "completed" {
+			break
+		}
+
+		time.Sleep(interval)
+		interval *= 2
+		if interval > maxPollInterval {
+			interval = maxPollInterval
+		}
+	}
 
-	// TODO generate survey prompts for the inputs
-	// TODO validate whatever input we got
-	// TODO create the dispatch event
+	if run.Conclusion != "success" {
+		return fmt.Errorf("run %s concluded with %s", run.HTMLURL, run.Conclusion)
+	}
 
 	return nil
 }
 
+// findDispatchedRun looks for the workflow_dispatch run that was just
+// created, since the dispatches API itself returns 204 with no run ID. The
+// branch/event/created-time filters narrow the candidates, but only
+// head_sha (and workflow ID, when it's numeric) is matched against each
+// candidate. Two dispatches of the same workflow at the same commit are not
+// told apart; the first matching run in the response is returned.
+func findDispatchedRun(client *api.Client, repo ghrepo.Interface, workflowID string, ref string, headSHA string, dispatchedAt time.Time) (*workflowRun, error) {
+	path := fmt.Sprintf("repos/%s/actions/workflows/%s/runs?event=workflow_dispatch&branch=%s&created=%s",
+		ghrepo.FullName(repo), workflowID, url.QueryEscape(ref), url.QueryEscape(">="+dispatchedAt.UTC().Format(time.RFC3339)))
+
+	numericWorkflowID, hasNumericWorkflowID := int64(0), false
+	if n, err := strconv.ParseInt(workflowID, 10, 64); err == nil {
+		numericWorkflowID, hasNumericWorkflowID = n, true
+	}
+
+	type result struct {
+		WorkflowRuns []workflowRun `json:"workflow_runs"`
+	}
+
+	// The run may not be visible for a few seconds after the dispatch API
+	// call returns, so poll for it the same way we poll for status updates.
+	interval := minPollInterval
+	for attempt := 0; attempt < 10; attempt++ {
+		var res result
+		if err := client.REST(repo.RepoHost(), "GET", path, nil, &res); err != nil {
+			return nil, err
+		}
+
+		for _, run := range res.WorkflowRuns {
+			if run.HeadSHA != headSHA {
+				continue
+			}
+			if hasNumericWorkflowID && run.WorkflowID != numericWorkflowID {
+				continue
+			}
+			run := run
+			return &run, nil
+		}
+
+		time.Sleep(interval)
+		interval *= 2
+		if interval > maxPollInterval {
+			interval = maxPollInterval
+		}
+	}
+
+	return nil, errors.New("timed out waiting for the dispatched run to appear")
+}
+
+func getRun(client *api.Client, repo ghrepo.Interface, runID int64) (*workflowRun, error) {
+	path := fmt.Sprintf("repos/%s/actions/runs/%d", ghrepo.FullName(repo), runID)
+
+	var run workflowRun
+	if err := client.REST(repo.RepoHost(), "GET", path, nil, &run); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func getRunJobs(client *api.Client, repo ghrepo.Interface, runID int64) ([]workflowRunJob, error) {
+	path := fmt.Sprintf("repos/%s/actions/runs/%d/jobs", ghrepo.FullName(repo), runID)
+
+	type result struct {
+		Jobs []workflowRunJob `json:"jobs"`
+	}
+
+	var res result
+	if err := client.REST(repo.RepoHost(), "GET", path, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Jobs, nil
+}
+
+// printRunStatus renders a live-updating summary of a run's jobs and steps,
+// using color when the terminal supports it.
+func printRunStatus(io *iostreams.IOStreams, cs *iostreams.ColorScheme, run *workflowRun, jobs []workflowRunJob) {
+	if io.IsStdoutTTY() {
+		fmt.Fprint(io.Out, "\x1b[H\x1b[2J")
+	}
+
+	fmt.Fprintf(io.Out, "%s\n", statusSymbol(cs, run.Status, run.Conclusion)+" Run: "+run.Status)
+	for _, job := range jobs {
+		fmt.Fprintf(io.Out, "  %s %s\n", statusSymbol(cs, job.Status, job.Conclusion), job.Name)
+		for _, step := range job.Steps {
+			fmt.Fprintf(io.Out, "    %s %s\n", statusSymbol(cs, step.Status, step.Conclusion), step.Name)
+		}
+	}
+}
+
+func statusSymbol(cs *iostreams.ColorScheme, status string, conclusion string) string {
+	if status != "completed" {
+		return cs.Yellow("●")
+	}
+	switch conclusion {
+	case "success":
+		return cs.SuccessIconWithColor(cs.Green)
+	case "skipped", "neutral":
+		return cs.Gray("○")
+	default:
+		return cs.FailureIcon()
+	}
+}
+
+// collectInputs prompts the user for each workflow input, keyed on its
+// declared type, pre-filling defaults and enforcing `Required` inputs.
+func collectInputs(inputs map[string]WorkflowInput, environments []string) (map[string]string, error) {
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providedInputs := map[string]string{}
+
+	for _, name := range names {
+		input := inputs[name]
+
+		message := name
+		if input.Description != "" {
+			message = fmt.Sprintf("%s (%s)", name, input.Description)
+		}
+
+		var answer string
+		var err error
+
+		switch input.Type {
+		case workflowInputTypeBoolean:
+			defaultValue, _ := strconv.ParseBool(input.Default)
+			confirmed := defaultValue
+			err = prompt.SurveyAskOne(&survey.Confirm{Message: message, Default: defaultValue}, &confirmed)
+			answer = strconv.FormatBool(confirmed)
+		case workflowInputTypeChoice:
+			err = prompt.SurveyAskOne(&survey.Select{Message: message, Options: input.Options, Default: input.Default}, &answer)
+		case workflowInputTypeEnvironment:
+			err = prompt.SurveyAskOne(&survey.Select{Message: message, Options: environments}, &answer)
+		default:
+			q := &survey.Input{Message: message, Default: input.Default}
+			if input.Required {
+				err = prompt.SurveyAskOne(q, &answer, survey.WithValidator(survey.Required))
+			} else {
+				err = prompt.SurveyAskOne(q, &answer)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not prompt for input '%s': %w", name, err)
+		}
+
+		if err := validateProvidedInput(input, answer, environments); err != nil {
+			return nil, fmt.Errorf("input '%s': %w", name, err)
+		}
+
+		providedInputs[name] = answer
+	}
+
+	return providedInputs, nil
+}
+
+// createDispatch posts a workflow_dispatch event for the workflow identified
+// by workflowID (its numeric ID or its file name, both of which the API
+// accepts) at ref with the given inputs. The dispatch endpoint returns 204 with no body, so there is
+// no run ID to report back to the caller on success.
+func createDispatch(client *api.Client, repo ghrepo.Interface, workflowID string, ref string, inputs map[string]string) error {
+	path := fmt.Sprintf("repos/%s/actions/workflows/%s/dispatches", ghrepo.FullName(repo), workflowID)
+
+	requestByte, err := json.Marshal(map[string]interface{}{
+		"ref":    ref,
+		"inputs": inputs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to serialize workflow dispatch input: %w", err)
+	}
+
+	return client.REST(repo.RepoHost(), "POST", path, bytes.NewReader(requestByte), nil)
+}
+
+// pushEphemeralWorkflow commits content to .github/workflows/<basename of
+// path> on a throwaway branch off the repo's default branch and pushes it
+// to "origin" so it can be dispatched remotely, returning the name of the
+// pushed branch and a cleanup func that deletes it again (leaving the
+// branch in place when keepBranch is set) and removes the scratch clone.
+// It clones into a scratch directory rather than
+// touching the user's own working tree, so their checkout and HEAD are
+// left exactly as they were. token authenticates both the clone and the
+// push, since go-git does not consult git's credential helpers on its own.
+func pushEphemeralWorkflow(client *api.Client, repo ghrepo.Interface, token string, path string, content []byte, keepBranch bool) (ref string, cleanup func(), err error) {
+	noop := func() {}
+
+	auth := &githttp.BasicAuth{Username: "x-access-token", Password: token}
+
+	localRepo, err := git.PlainOpen(".")
+	if err != nil {
+		return "", noop, fmt.Errorf("--from-file requires running inside a git repository: %w", err)
+	}
+
+	remote, err := localRepo.Remote("origin")
+	if err != nil {
+		return "", noop, errors.New("no 'origin' remote found; add one with push access to use --from-file")
+	}
+	remoteURLs := remote.Config().URLs
+	if len(remoteURLs) == 0 {
+		return "", noop, errors.New("'origin' remote has no URL configured")
+	}
+
+	defaultBranch, err := api.RepoDefaultBranch(client, repo)
+	if err != nil {
+		return "", noop, fmt.Errorf("unable to determine default branch for %s: %w", ghrepo.FullName(repo), err)
+	}
+
+	scratchDir, err := ioutil.TempDir("", "gh-cli-dispatch")
+	if err != nil {
+		return "", noop, fmt.Errorf("unable to create a scratch directory: %w", err)
+	}
+	removeScratchDir := func() { _ = os.RemoveAll(scratchDir) }
+
+	scratchRepo, err := git.PlainClone(scratchDir, false, &git.CloneOptions{
+		URL:           remoteURLs[0],
+		Auth:          auth,
+		ReferenceName: plumbing.NewBranchReferenceName(defaultBranch),
+		SingleBranch:  true,
+		Depth:         1,
+	})
+	if err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to clone %s at %s: %w", remoteURLs[0], defaultBranch, err)
+	}
+
+	worktree, err := scratchRepo.Worktree()
+	if err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to open scratch worktree: %w", err)
+	}
+
+	branchName := fmt.Sprintf("gh-cli/dispatch/%d-%s", time.Now().Unix(), randomSuffix())
+	branchRefName := plumbing.NewBranchReferenceName(branchName)
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: branchRefName, Create: true}); err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to create temporary branch %s: %w", branchName, err)
+	}
+
+	workflowPath := filepath.Join(".github", "workflows", filepath.Base(path))
+	if err := ioutil.WriteFile(filepath.Join(worktree.Filesystem.Root(), workflowPath), content, 0644); err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to write %s: %w", workflowPath, err)
+	}
+
+	if _, err := worktree.Add(workflowPath); err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to stage %s: %w", workflowPath, err)
+	}
+
+	_, err = worktree.Commit(fmt.Sprintf("dispatch: run %s", filepath.Base(path)), &git.CommitOptions{
+		Author: &object.Signature{Name: "gh", Email: "gh@localhost", When: time.Now()},
+	})
+	if err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to commit %s: %w", workflowPath, err)
+	}
+
+	pushRefSpec := gitconfig.RefSpec(branchRefName + ":" + branchRefName)
+	if err := scratchRepo.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []gitconfig.RefSpec{pushRefSpec}, Auth: auth}); err != nil {
+		removeScratchDir()
+		return "", noop, fmt.Errorf("unable to push %s (check that your token has push access to this repository): %w", branchName, err)
+	}
+
+	cleanup = func() {
+		defer removeScratchDir()
+		if keepBranch {
+			return
+		}
+		deleteRefSpec := gitconfig.RefSpec(":" + branchRefName)
+		_ = scratchRepo.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []gitconfig.RefSpec{deleteRefSpec}, Auth: auth})
+	}
+
+	return branchName, cleanup, nil
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "0000"
+	}
+	return hex.EncodeToString(b)
+}
+
 type WorkflowInput struct {
 	// TODO i'd put Name in here but that's not how the yaml is structured. decide if things should be inconsistent or not.
 	Required    bool
 	Default     string
 	Description string
+	Type        string
+	Options     []string
+}
+
+// workflowFile is a minimal typed view of a workflow's top level keys, just
+// enough to get at the `workflow_dispatch` trigger regardless of which of
+// the three shapes YAML allows for `on:` was used:
+//
+//	on: workflow_dispatch
+//	on: [push, workflow_dispatch]
+//	on:
+//	  workflow_dispatch:
+//	    inputs: {...}
+type workflowFile struct {
+	RawOn yaml.Node `yaml:"on"`
 }
 
 func findInputs(yamlContent []byte) (map[string]WorkflowInput, error) {
-	var rootNode yaml.Node
-	err := yaml.Unmarshal(yamlContent, &rootNode)
+	var wf workflowFile
+	err := yaml.Unmarshal(yamlContent, &wf)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse workflow YAML: %w", err)
 	}
 
-	if len(rootNode.Content) != 1 {
-		return nil, errors.New("invalid yaml file")
-	}
-
-	var onKeyNode *yaml.Node
-	var dispatchKeyNode *yaml.Node
-	var inputsKeyNode *yaml.Node
-	var inputsMapNode *yaml.Node
-
-	// TODO this is pretty hideous
-	for _, node := range rootNode.Content[0].Content {
-		if onKeyNode != nil {
-			for _, node := range node.Content {
-				if dispatchKeyNode != nil {
-					for _, node := range node.Content {
-						if inputsKeyNode != nil {
-							inputsMapNode = node
-							break
-						}
-						if node.Value == "inputs" {
-							inputsKeyNode = node
-						}
-					}
-					break
-				}
-				if node.Value == "workflow_dispatch" {
-					dispatchKeyNode = node
-				}
-			}
-			break
+	dispatchNode, err := findDispatchNode(&wf.RawOn)
+	if err != nil {
+		return nil, err
+	}
+	if dispatchNode == nil {
+		return nil, errors.New("unable to manually run a workflow without a workflow_dispatch event")
+	}
+
+	out := map[string]WorkflowInput{}
+
+	if dispatchNode.Kind != yaml.MappingNode {
+		// `on: workflow_dispatch` with no inputs configured.
+		return out, nil
+	}
+
+	for i := 0; i+1 < len(dispatchNode.Content); i += 2 {
+		if dispatchNode.Content[i].Value != "inputs" {
+			continue
 		}
-		if strings.EqualFold(node.Value, "on") {
-			onKeyNode = node
+		if err := dispatchNode.Content[i+1].Decode(&out); err != nil {
+			return nil, fmt.Errorf("could not decode workflow inputs: %w", err)
 		}
+		break
 	}
 
-	if onKeyNode == nil {
+	for name, input := range out {
+		if err := validateInputType(input); err != nil {
+			return nil, fmt.Errorf("input '%s': %w", name, err)
+		}
+	}
+
+	return out, nil
+}
+
+// findDispatchNode locates the node describing the `workflow_dispatch`
+// trigger within the raw `on:` node, whatever shape that node takes. A nil
+// result with a nil error means there is a valid `on:` key but it does not
+// include `workflow_dispatch`.
+func findDispatchNode(onNode *yaml.Node) (*yaml.Node, error) {
+	if onNode.Kind == 0 {
 		return nil, errors.New("invalid workflow: no 'on' key")
 	}
 
-	if dispatchKeyNode == nil {
-		return nil, errors.New("unable to manually run a workflow without a workflow_dispatch event")
+	switch onNode.Kind {
+	case yaml.ScalarNode:
+		// on: workflow_dispatch
+		if onNode.ShortTag() != "!!str" {
+			return nil, errors.New("invalid workflow: unrecognized 'on' key")
+		}
+		if onNode.Value == "workflow_dispatch" {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: "workflow_dispatch"}, nil
+		}
+		return nil, nil
+	case yaml.SequenceNode:
+		// on: [push, workflow_dispatch]
+		for _, event := range onNode.Content {
+			if event.Value == "workflow_dispatch" {
+				return &yaml.Node{Kind: yaml.ScalarNode, Value: "workflow_dispatch"}, nil
+			}
+		}
+		return nil, nil
+	case yaml.MappingNode:
+		// on: { workflow_dispatch: { inputs: ... } }
+		for i := 0; i+1 < len(onNode.Content); i += 2 {
+			if onNode.Content[i].Value == "workflow_dispatch" {
+				return onNode.Content[i+1], nil
+			}
+		}
+		return nil, nil
+	default:
+		return nil, errors.New("invalid workflow: unrecognized 'on' key")
 	}
+}
 
-	out := map[string]WorkflowInput{}
+// validateInputType checks that an input's declared type is one findInputs
+// callers can act on, producing a descriptive error for a choice input
+// without options. Number inputs are not checked here. Unknown types are left to fall back to plain string
+// handling by the caller, so they are not an error here.
+func validateInputType(input WorkflowInput) error {
+	switch input.Type {
+	case "", workflowInputTypeString, workflowInputTypeBoolean, workflowInputTypeEnvironment:
+		return nil
+	case workflowInputTypeChoice:
+		if len(input.Options) == 0 {
+			return errors.New("choice input is missing 'options'")
+		}
+		return nil
+	case workflowInputTypeNumber:
+		return nil
+	default:
+		return nil
+	}
+}
 
-	if inputsKeyNode == nil || inputsMapNode == nil {
-		return out, nil
+// validateProvidedInput checks a user-provided value against an input's
+// declared type, returning a descriptive error if it won't be accepted by
+// the dispatch API. `environments` is only consulted for inputs of type
+// `environment` and may be nil otherwise.
+func validateProvidedInput(input WorkflowInput, value string, environments []string) error {
+	switch input.Type {
+	case workflowInputTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a valid boolean", value)
+		}
+	case workflowInputTypeChoice:
+		for _, option := range input.Options {
+			if option == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of the allowed options: %s", value, strings.Join(input.Options, ", "))
+	case workflowInputTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a valid number", value)
+		}
+	case workflowInputTypeEnvironment:
+		for _, environment := range environments {
+			if environment == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not an environment configured on this repository", value)
 	}
+	return nil
+}
 
-	err = inputsMapNode.Decode(&out)
-	if err != nil {
-		return nil, fmt.Errorf("could not decode workflow inputs: %w", err)
+// fetchRepoEnvironments returns the names of the deployment environments
+// configured on repo, used to validate `environment`-typed inputs before
+// dispatch.
+func fetchRepoEnvironments(client *api.Client, repo ghrepo.Interface) ([]string, error) {
+	path := fmt.Sprintf("repos/%s/environments", ghrepo.FullName(repo))
+
+	type environment struct {
+		Name string
+	}
+	type result struct {
+		Environments []environment
 	}
 
-	return out, nil
+	var res result
+	if err := client.REST(repo.RepoHost(), "GET", path, nil, &res); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(res.Environments))
+	for i, e := range res.Environments {
+		names[i] = e.Name
+	}
+	return names, nil
 }
 
 func getWorkflowContent(client *api.Client, repo ghrepo.Interface, workflow *shared.Workflow, ref string) ([]byte, error) {
@@ -274,3 +966,39 @@ func getWorkflowContent(client *api.Client, repo ghrepo.Interface, workflow *sha
 
 	return decoded, nil
 }
+
+// resolveFromFileWorkflowID looks for a workflow already registered on the
+// repo's default branch at .github/workflows/<basename of path>. The
+// workflow_dispatch event can only be triggered for a workflow that the
+// default branch already declares that trigger for, so pushing a
+// not-yet-registered file to a throwaway branch and dispatching against it
+// would just be rejected by the API; this fails fast with an explanation
+// instead.
+func resolveFromFileWorkflowID(client *api.Client, repo ghrepo.Interface, path string) (string, error) {
+	wantPath := filepath.ToSlash(filepath.Join(".github", "workflows", filepath.Base(path)))
+
+	type response struct {
+		Workflows []struct {
+			ID    int64  `json:"id"`
+			Path  string `json:"path"`
+			State string `json:"state"`
+		} `json:"workflows"`
+	}
+
+	var res response
+	p := fmt.Sprintf("repos/%s/actions/workflows", ghrepo.FullName(repo))
+	if err := client.REST(repo.RepoHost(), "GET", p, nil, &res); err != nil {
+		return "", fmt.Errorf("could not list workflows: %w", err)
+	}
+
+	for _, w := range res.Workflows {
+		if w.Path == wantPath {
+			return fmt.Sprintf("%d", w.ID), nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"%s is not yet registered as a workflow on this repository's default branch; "+
+			"GitHub can only dispatch a workflow_dispatch event for a workflow that the default "+
+			"branch already declares that trigger for, so merge it there first", wantPath)
+}
@@ -0,0 +1,223 @@
+package run
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cli/cli/internal/ghrepo"
+	"github.com/cli/cli/pkg/httpmock"
+	"github.com/cli/cli/pkg/iostreams"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_findInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		wantErr  string
+		wantKeys []string
+	}{
+		{
+			name: "scalar on, no inputs",
+			yaml: "on: workflow_dispatch\njobs: {}\n",
+		},
+		{
+			name: "sequence on, no inputs",
+			yaml: "on: [push, workflow_dispatch]\njobs: {}\n",
+		},
+		{
+			name:     "mapping on with inputs",
+			yaml:     "on:\n  workflow_dispatch:\n    inputs:\n      name:\n        required: true\n      env:\n        type: choice\n        options: [staging, prod]\njobs: {}\n",
+			wantKeys: []string{"name", "env"},
+		},
+		{
+			name:    "no workflow_dispatch trigger",
+			yaml:    "on: push\njobs: {}\n",
+			wantErr: "unable to manually run a workflow without a workflow_dispatch event",
+		},
+		{
+			name:    "no on key",
+			yaml:    "jobs: {}\n",
+			wantErr: "invalid workflow: no 'on' key",
+		},
+		{
+			name:    "choice input missing options",
+			yaml:    "on:\n  workflow_dispatch:\n    inputs:\n      env:\n        type: choice\njobs: {}\n",
+			wantErr: "choice input is missing 'options'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs, err := findInputs([]byte(tt.yaml))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var keys []string
+			for k := range inputs {
+				keys = append(keys, k)
+			}
+			assert.ElementsMatch(t, tt.wantKeys, keys)
+		})
+	}
+}
+
+func Test_findDispatchNode(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantNil bool
+		wantErr string
+	}{
+		{name: "scalar match", yaml: "on: workflow_dispatch\n"},
+		{name: "scalar no match", yaml: "on: push\n", wantNil: true},
+		{name: "sequence match", yaml: "on: [push, workflow_dispatch]\n"},
+		{name: "sequence no match", yaml: "on: [push, pull_request]\n", wantNil: true},
+		{name: "mapping match", yaml: "on:\n  workflow_dispatch: {}\n"},
+		{name: "mapping no match", yaml: "on:\n  push: {}\n", wantNil: true},
+		{name: "unrecognized on key", yaml: "on: 5\n", wantErr: "invalid workflow: unrecognized 'on' key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wf workflowFile
+			require.NoError(t, yaml.Unmarshal([]byte(tt.yaml), &wf))
+			node, err := findDispatchNode(&wf.RawOn)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, node)
+			} else {
+				assert.NotNil(t, node)
+			}
+		})
+	}
+}
+
+func Test_validateProvidedInput(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        WorkflowInput
+		value        string
+		environments []string
+		wantErr      string
+	}{
+		{name: "valid boolean", input: WorkflowInput{Type: workflowInputTypeBoolean}, value: "true"},
+		{name: "invalid boolean", input: WorkflowInput{Type: workflowInputTypeBoolean}, value: "yes", wantErr: "not a valid boolean"},
+		{name: "valid choice", input: WorkflowInput{Type: workflowInputTypeChoice, Options: []string{"a", "b"}}, value: "a"},
+		{name: "invalid choice", input: WorkflowInput{Type: workflowInputTypeChoice, Options: []string{"a", "b"}}, value: "c", wantErr: "not one of the allowed options"},
+		{name: "valid number", input: WorkflowInput{Type: workflowInputTypeNumber}, value: "3.14"},
+		{name: "invalid number", input: WorkflowInput{Type: workflowInputTypeNumber}, value: "abc", wantErr: "not a valid number"},
+		{name: "valid environment", input: WorkflowInput{Type: workflowInputTypeEnvironment}, value: "prod", environments: []string{"staging", "prod"}},
+		{name: "invalid environment", input: WorkflowInput{Type: workflowInputTypeEnvironment}, value: "prod", environments: []string{"staging"}, wantErr: "not an environment configured"},
+		{name: "plain string always valid", input: WorkflowInput{Type: workflowInputTypeString}, value: "anything"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProvidedInput(tt.input, tt.value, tt.environments)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_resolveInputs(t *testing.T) {
+	workflowYAML := []byte("on:\n  workflow_dispatch:\n    inputs:\n      name:\n        required: true\n      flag:\n        type: boolean\n        default: \"false\"\njobs: {}\n")
+
+	tests := []struct {
+		name    string
+		opts    *RunOptions
+		stubs   func(*httpmock.Registry)
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "from JSON",
+			opts: &RunOptions{JSON: `{"name":"alice","flag":"true"}`},
+			want: map[string]string{"name": "alice", "flag": "true"},
+		},
+		{
+			name:    "from JSON, missing required input",
+			opts:    &RunOptions{JSON: `{"flag":"true"}`},
+			wantErr: "missing required input 'name'",
+		},
+		{
+			name: "from InputArgs",
+			opts: &RunOptions{InputArgs: []string{"--name", "bob", "--flag"}},
+			want: map[string]string{"name": "bob", "flag": "true"},
+		},
+		{
+			name:    "from InputArgs, missing required input",
+			opts:    &RunOptions{InputArgs: []string{"--flag"}},
+			wantErr: "missing required input 'name'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			io, _, _, stderr := iostreams.Test()
+			tt.opts.IO = io
+			tt.opts.HttpClient = func() (*http.Client, error) {
+				reg := &httpmock.Registry{}
+				if tt.stubs != nil {
+					tt.stubs(reg)
+				}
+				return &http.Client{Transport: reg}, nil
+			}
+			tt.opts.BaseRepo = func() (ghrepo.Interface, error) {
+				return ghrepo.New("OWNER", "REPO"), nil
+			}
+
+			got, err := resolveInputs(tt.opts, workflowYAML)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
+
+func Test_resolveInputs_environment(t *testing.T) {
+	workflowYAML := []byte("on:\n  workflow_dispatch:\n    inputs:\n      target:\n        type: environment\njobs: {}\n")
+
+	io, _, _, _ := iostreams.Test()
+	reg := &httpmock.Registry{}
+	reg.Register(
+		httpmock.REST("GET", "repos/OWNER/REPO/environments"),
+		httpmock.StringResponse(`{"environments":[{"name":"staging"},{"name":"prod"}]}`),
+	)
+	defer reg.Verify(t)
+
+	opts := &RunOptions{
+		IO:   io,
+		JSON: `{"target":"prod"}`,
+		HttpClient: func() (*http.Client, error) {
+			return &http.Client{Transport: reg}, nil
+		},
+		BaseRepo: func() (ghrepo.Interface, error) {
+			return ghrepo.New("OWNER", "REPO"), nil
+		},
+	}
+
+	got, err := resolveInputs(opts, workflowYAML)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"target": "prod"}, got)
+}